@@ -0,0 +1,119 @@
+package nut
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// defaultCommandTimeout bounds how long CommandHandler may spend
+// dialing and issuing a single command, the same way defaultTimeout
+// bounds a single host's scrape.
+const defaultCommandTimeout = 10 * time.Second
+
+// CommandRequest is the JSON body accepted by CommandHandler. Setting
+// Variable issues SET VAR; otherwise Command issues INSTCMD.
+type CommandRequest struct {
+	Host    string   `json:"host"`
+	UPS     string   `json:"ups"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+
+	Variable string `json:"variable,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// CommandHandler returns an http.Handler that accepts POST requests
+// with a JSON-encoded CommandRequest body and issues the
+// corresponding INSTCMD or SET VAR against one of hosts, keyed by
+// HostConfig.Host. The dial and the command are bounded by
+// defaultCommandTimeout, so a hung upsd fails the request instead of
+// blocking the handler goroutine indefinitely. Wrap the returned
+// handler with RequireToken, or equivalent middleware, before
+// exposing it: INSTCMD and SET VAR can affect physical hardware.
+func CommandHandler(hosts map[string]HostConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req CommandRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := validateArgs(append([]string{req.UPS, req.Command, req.Variable}, req.Args...)...); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		host, ok := hosts[req.Host]
+		if !ok {
+			http.Error(w, "unknown host", http.StatusNotFound)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), defaultCommandTimeout)
+		defer cancel()
+
+		conn, err := DialContext(ctx, host.Host, host.dialConfig())
+		if err != nil {
+			http.Error(w, "error connecting to NUT server: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			if err := conn.SetDeadline(deadline); err != nil {
+				http.Error(w, "error setting deadline: "+err.Error(), http.StatusBadGateway)
+				return
+			}
+		}
+
+		if req.Variable != "" {
+			err = conn.SetVariable(req.UPS, req.Variable, req.Value)
+		} else {
+			err = conn.InstantCommand(req.UPS, req.Command, req.Args...)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), statusForErr(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// statusForErr maps the errors returned by InstantCommand and
+// SetVariable to HTTP status codes.
+func statusForErr(err error) int {
+	switch {
+	case errors.Is(err, ErrAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, ErrCmdNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, ErrInvalidArgument):
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// RequireToken wraps h, rejecting any request whose Authorization
+// header is not "Bearer token". It is meant to guard CommandHandler.
+func RequireToken(token string, h http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if len(got) != len(want) || subtle.ConstantTimeCompare(got, want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
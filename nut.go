@@ -6,12 +6,17 @@ package nut
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -22,19 +27,74 @@ type Client struct {
 	br   *bufio.Reader
 }
 
+// DialConfig carries the optional credentials and TLS settings used
+// by Dial to authenticate and secure a connection to a NUT server.
+type DialConfig struct {
+	// Username and Password are sent via Login if Username is set.
+	Username string
+	Password string
+
+	// TLSConfig, if set, is used to upgrade the connection with
+	// StartTLS before authenticating.
+	TLSConfig *tls.Config
+
+	// RequireTLS fails Dial if upsd rejects the STARTTLS command.
+	// Otherwise Dial falls back to the plaintext connection in that
+	// case. It has no effect once upsd has accepted STARTTLS: a
+	// failed handshake past that point always fails Dial, since the
+	// connection is no longer usable as plaintext either.
+	RequireTLS bool
+}
+
 // Dial dials a NUT server using TCP. If the address does not contain
-// a port number, it will default to 3493.
-func Dial(addr string) (*Client, error) {
+// a port number, it will default to 3493. cfg may be nil to dial
+// without authentication or TLS.
+func Dial(addr string, cfg *DialConfig) (*Client, error) {
+	return DialContext(context.Background(), addr, cfg)
+}
+
+// DialContext is like Dial, but bounds the TCP connect by ctx, and
+// the STARTTLS/Login exchange that follows by ctx's deadline, if it
+// has one. That deadline stays set on the returned Client; use
+// (*Client).SetDeadline to change it. Cancelling ctx after the TCP
+// connect does not interrupt the exchange.
+func DialContext(ctx context.Context, addr string, cfg *DialConfig) (*Client, error) {
 	_, _, err := net.SplitHostPort(addr)
 	if err != nil {
 		addr = net.JoinHostPort(addr, "3493")
 	}
 
-	conn, err := net.Dial("tcp", addr)
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
 	if err != nil {
 		return nil, err
 	}
-	return NewClient(conn), nil
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	c := NewClient(conn)
+
+	if cfg != nil && cfg.TLSConfig != nil {
+		if err := c.StartTLS(cfg.TLSConfig); err != nil {
+			if cfg.RequireTLS || errors.Is(err, errTLSHandshakeFailed) {
+				_ = c.Close()
+				return nil, err
+			}
+			log.Printf("nut: STARTTLS rejected for %s, falling back to plaintext: %s", addr, err)
+		}
+	}
+
+	if cfg != nil && cfg.Username != "" {
+		if err := c.Login(cfg.Username, cfg.Password); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
 }
 
 // NewClient wraps an existing net.Conn.
@@ -47,6 +107,84 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
+// SetDeadline sets the read and write deadline on the underlying
+// connection, as per net.Conn.
+func (c *Client) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+// Login authenticates the connection with the given username and
+// password. It must be called before any LIST command that requires
+// an upsd ACL, e.g. when upsd restricts LIST to LISTEN clients.
+func (c *Client) Login(user, pass string) error {
+	if err := c.write("USERNAME " + user); err != nil {
+		return err
+	}
+	if err := c.expectOK(); err != nil {
+		return err
+	}
+	if err := c.write("PASSWORD " + pass); err != nil {
+		return err
+	}
+	return c.expectOK()
+}
+
+// StartTLS upgrades the connection to TLS. It must be called before
+// Login or any LIST command, and only once per connection.
+func (c *Client) StartTLS(cfg *tls.Config) error {
+	if err := c.write("STARTTLS"); err != nil {
+		return err
+	}
+	l, err := c.read()
+	if err != nil {
+		return err
+	}
+	if l != "OK STARTTLS" {
+		return fmt.Errorf("expected %q, got %q", "OK STARTTLS", l)
+	}
+
+	// upsd has now switched into TLS-listening mode, so the
+	// connection is unusable as plaintext from here on: a failed
+	// handshake must close it rather than leave it to be used as if
+	// nothing happened.
+	tlsConn := tls.Client(c.conn, cfg)
+	if err := tlsConn.Handshake(); err != nil {
+		_ = c.conn.Close()
+		return fmt.Errorf("%w: %s", errTLSHandshakeFailed, err)
+	}
+	c.conn = tlsConn
+	c.br = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// errTLSHandshakeFailed indicates the TLS handshake failed after
+// upsd had already accepted STARTTLS, leaving the connection
+// unusable even as plaintext.
+var errTLSHandshakeFailed = errors.New("nut: TLS handshake failed after STARTTLS was accepted")
+
+// expectOK reads a line and verifies it is "OK".
+func (c *Client) expectOK() error {
+	l, err := c.read()
+	if err != nil {
+		return err
+	}
+	if l != "OK" {
+		return fmt.Errorf("expected %q, got %q", "OK", l)
+	}
+	return nil
+}
+
+// Logout sends the NUT LOGOUT command, ending the session politely
+// before Close. upsd's reply text varies by version, so it is read
+// and discarded rather than validated.
+func (c *Client) Logout() error {
+	if err := c.write("LOGOUT"); err != nil {
+		return err
+	}
+	_, err := c.read()
+	return err
+}
+
 func (c *Client) list(typ string) ([]string, error) {
 	cmd := "LIST " + typ
 	if err := c.write(cmd); err != nil {
@@ -105,6 +243,28 @@ func (c *Client) Variables(ups string) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return parseVarList(lines)
+}
+
+// ReadWritable returns the writable variables and their current
+// values for a UPS, as reported by LIST RW.
+func (c *Client) ReadWritable(ups string) (map[string]string, error) {
+	lines, err := c.list("RW " + ups)
+	if err != nil {
+		return nil, err
+	}
+	return parseVarList(lines)
+}
+
+// Commands returns the instant commands supported by a UPS, as
+// reported by LIST CMD.
+func (c *Client) Commands(ups string) ([]string, error) {
+	return c.list("CMD " + ups)
+}
+
+// parseVarList parses the "name \"value\"" lines returned by LIST VAR
+// and LIST RW.
+func parseVarList(lines []string) (map[string]string, error) {
 	vars := map[string]string{}
 	for _, l := range lines {
 		idx := strings.IndexByte(l, ' ')
@@ -112,17 +272,95 @@ func (c *Client) Variables(ups string) (map[string]string, error) {
 			return nil, errors.New("protocol error")
 		}
 		k := l[:idx]
-		v := l[idx+1:]
-		v, err = strconv.Unquote(v)
+		v, err := strconv.Unquote(l[idx+1:])
 		if err != nil {
 			return nil, err
 		}
-
 		vars[k] = v
 	}
 	return vars, nil
 }
 
+// Errors returned by upsd in response to INSTCMD and SET VAR, as per
+// the NUT network protocol.
+var (
+	ErrAccessDenied    = errors.New("nut: access denied")
+	ErrCmdNotSupported = errors.New("nut: command not supported")
+	ErrInvalidArgument = errors.New("nut: invalid argument")
+)
+
+// errForUpsd maps the reason of an upsd "ERR reason" response to a
+// Go error value.
+func errForUpsd(reason string) error {
+	switch reason {
+	case "ACCESS-DENIED":
+		return ErrAccessDenied
+	case "CMD-NOT-SUPPORTED":
+		return ErrCmdNotSupported
+	case "INVALID-ARGUMENT":
+		return ErrInvalidArgument
+	default:
+		return fmt.Errorf("nut: %s", reason)
+	}
+}
+
+// readResult reads a single "OK" or "ERR reason" response line, as
+// returned by INSTCMD and SET VAR.
+func (c *Client) readResult() error {
+	l, err := c.read()
+	if err != nil {
+		return err
+	}
+	if l == "OK" {
+		return nil
+	}
+	if strings.HasPrefix(l, "ERR ") {
+		return errForUpsd(l[len("ERR "):])
+	}
+	return fmt.Errorf("expected %q, got %q", "OK", l)
+}
+
+// InstantCommand issues an instant command on a UPS, e.g.
+// "test.battery.start" or "beeper.mute". extra is appended as
+// additional parameters to the command, as per the NUT protocol.
+func (c *Client) InstantCommand(ups, cmd string, extra ...string) error {
+	fields := append([]string{ups, cmd}, extra...)
+	if err := validateArgs(fields...); err != nil {
+		return err
+	}
+	if err := c.write("INSTCMD " + strings.Join(fields, " ")); err != nil {
+		return err
+	}
+	return c.readResult()
+}
+
+// SetVariable sets a writable variable on a UPS, e.g.
+// "ups.delay.shutdown". value is not subject to validateArgs: it is
+// quoted with %q, which already escapes any newline it contains.
+func (c *Client) SetVariable(ups, name, value string) error {
+	if err := validateArgs(ups, name); err != nil {
+		return err
+	}
+	if err := c.write(fmt.Sprintf("SET VAR %s %s %q", ups, name, value)); err != nil {
+		return err
+	}
+	return c.readResult()
+}
+
+// validateArgs rejects arguments containing \r or \n: InstantCommand
+// and SetVariable join their arguments with spaces onto a single
+// line, so an embedded newline would let a caller smuggle an
+// additional, fully attacker-controlled NUT protocol command onto the
+// connection.
+func validateArgs(args ...string) error {
+	for _, a := range args {
+		if strings.ContainsAny(a, "\r\n") {
+			return fmt.Errorf("%w: argument %q contains a newline", ErrInvalidArgument, a)
+		}
+	}
+	return nil
+}
+
 func (c *Client) write(s string) error {
 	_, err := c.conn.Write([]byte(s + "\n"))
 	return err
@@ -215,58 +453,413 @@ var descriptions = map[string]struct {
 	"battery.packs.bad":       {"battery_packs_bad", "Number of bad battery packs"},
 }
 
+// statusFlags enumerates the tokens that can appear in the NUT
+// ups.status variable. The set is pre-declared so that
+// nut_ups_status is emitted with a stable set of time series
+// regardless of which flags happen to be set at scrape time.
+var statusFlags = []string{
+	"OL", "OB", "LB", "HB", "RB", "CHRG", "DISCHRG", "BYPASS",
+	"CAL", "OFF", "OVER", "TRIM", "BOOST", "FSD",
+}
+
+// selfTestResults enumerates the values the NUT ups.test.result
+// variable can take.
+var selfTestResults = []string{
+	"OK", "FAILED", "WARNING", "IN PROGRESS", "NO TEST INITIATED",
+}
+
+// HostConfig describes a NUT server to scrape and how to connect to
+// it.
+type HostConfig struct {
+	// Host is the address passed to Dial, e.g. "upsd.example.com:3493".
+	Host string
+
+	// Username and Password, if Username is set, are used to
+	// authenticate via Login.
+	Username string
+	Password string
+
+	// TLSConfig, if set, is used to upgrade the connection with
+	// StartTLS.
+	TLSConfig *tls.Config
+
+	// RequireTLS fails the scrape of this host if STARTTLS does not
+	// succeed.
+	RequireTLS bool
+}
+
+func (h HostConfig) dialConfig() *DialConfig {
+	return &DialConfig{
+		Username:   h.Username,
+		Password:   h.Password,
+		TLSConfig:  h.TLSConfig,
+		RequireTLS: h.RequireTLS,
+	}
+}
+
+// defaultConcurrency is the number of hosts scraped in parallel when
+// no WithConcurrency option is given.
+const defaultConcurrency = 8
+
+// defaultTimeout bounds how long a single host's scrape may take when
+// no WithTimeout option is given.
+const defaultTimeout = 10 * time.Second
+
+// CollectorOption configures the Collector returned by NewCollector.
+type CollectorOption func(*nutCollector)
+
+// WithConcurrency bounds the number of hosts scraped in parallel.
+func WithConcurrency(n int) CollectorOption {
+	return func(c *nutCollector) {
+		c.concurrency = n
+	}
+}
+
+// WithTimeout bounds how long the scrape of a single host may take,
+// including connecting, authenticating and reading all of its UPSs.
+func WithTimeout(d time.Duration) CollectorOption {
+	return func(c *nutCollector) {
+		c.timeout = d
+	}
+}
+
+// defaultMaxIdle is how long a cached connection may sit unused
+// before it is closed instead of reused, when no WithMaxIdle option
+// is given.
+const defaultMaxIdle = 5 * time.Minute
+
+// WithMaxIdle bounds how long a cached connection to a host may sit
+// unused before it is closed instead of reused.
+func WithMaxIdle(d time.Duration) CollectorOption {
+	return func(c *nutCollector) {
+		c.maxIdle = d
+	}
+}
+
+// defaultLabelVars are the NUT variables labelled on every metric
+// when no WithLabels option is given.
+var defaultLabelVars = []string{"device.model", "device.mfr", "device.serial", "device.type"}
+
+// WithLabels sets the NUT variables used as labels on every metric,
+// replacing the default of device.model, device.mfr, device.serial
+// and device.type. The Prometheus label name for each variable is
+// its last dot-separated segment, e.g. "ups.location" becomes the
+// "location" label. NewCollector panics if two vars derive the same
+// label, e.g. "device.serial" and "ups.serial".
+func WithLabels(vars []string) CollectorOption {
+	return func(c *nutCollector) {
+		c.labelVars = vars
+	}
+}
+
+// labelName derives a Prometheus label name from a NUT variable name
+// by taking its last dot-separated segment, e.g. "device.model"
+// becomes "model".
+func labelName(v string) string {
+	if idx := strings.LastIndexByte(v, '.'); idx != -1 {
+		return v[idx+1:]
+	}
+	return v
+}
+
 // NewCollector returns a Prometheus collector, collecting statistics
 // from all UPSs on the hosts.
-func NewCollector(hosts []string) prometheus.Collector {
+func NewCollector(hosts []HostConfig, opts ...CollectorOption) prometheus.Collector {
 	const namespace = "nut"
 
+	c := &nutCollector{
+		hosts:       hosts,
+		concurrency: defaultConcurrency,
+		timeout:     defaultTimeout,
+		labelVars:   defaultLabelVars,
+		maxIdle:     defaultMaxIdle,
+		conns:       map[string]*cachedConn{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	labelNames := make([]string, len(c.labelVars))
+	seenBy := map[string]string{}
+	for i, v := range c.labelVars {
+		name := labelName(v)
+		if other, ok := seenBy[name]; ok {
+			panic(fmt.Sprintf("nut: WithLabels vars %q and %q both derive the same label %q", other, v, name))
+		}
+		seenBy[name] = v
+		labelNames[i] = name
+	}
+
 	descs := map[string]*prometheus.Desc{}
 	for k, v := range descriptions {
 		descs[k] = prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", v.name),
 			v.desc,
-			[]string{"model", "mfr", "serial", "type"},
+			labelNames,
 			nil,
 		)
 	}
+	c.descs = descs
 
-	return &nutCollector{
-		hosts: hosts,
-		descs: descs,
-	}
+	c.statusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "ups_status"),
+		"UPS status flag from ups.status (1 if set, 0 if not)",
+		append(append([]string{}, labelNames...), "flag"),
+		nil,
+	)
+	c.selfTestDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "ups_selftest_status"),
+		"Result of the last UPS self-test, from ups.test.result",
+		append(append([]string{}, labelNames...), "result"),
+		nil,
+	)
+
+	c.upDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "up"),
+		"Whether the last scrape of the NUT server succeeded",
+		[]string{"host"},
+		nil,
+	)
+	c.scrapeDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "scrape_duration_seconds"),
+		"Duration of the scrape of the NUT server",
+		[]string{"host"},
+		nil,
+	)
+	c.scrapeErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "scrape_error"),
+		"Whether the last scrape of the NUT server encountered an error",
+		[]string{"host"},
+		nil,
+	)
+	c.upsScrapeDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "ups_scrape_duration_seconds"),
+		"Duration of reading the variables of a single UPS",
+		[]string{"host", "ups"},
+		nil,
+	)
+
+	return c
 }
 
 type nutCollector struct {
-	hosts []string
-	descs map[string]*prometheus.Desc
+	hosts        []HostConfig
+	descs        map[string]*prometheus.Desc
+	statusDesc   *prometheus.Desc
+	selfTestDesc *prometheus.Desc
+
+	upDesc                *prometheus.Desc
+	scrapeDurationDesc    *prometheus.Desc
+	scrapeErrorDesc       *prometheus.Desc
+	upsScrapeDurationDesc *prometheus.Desc
+
+	concurrency int
+	timeout     time.Duration
+	labelVars   []string
+
+	maxIdle time.Duration
+	mu      sync.Mutex
+	conns   map[string]*cachedConn
+}
+
+// cachedConn is a connection kept open across scrapes for a single
+// host.
+type cachedConn struct {
+	client   *Client
+	lastUsed time.Time
+
+	// mu guards the use of client: getConn returns a cachedConn
+	// already locked, so that only one goroutine at a time writes a
+	// command and reads its response on a given connection. The
+	// caller must unlock it once done.
+	mu sync.Mutex
 }
 
 func (c *nutCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, v := range c.descs {
 		ch <- v
 	}
+	ch <- c.statusDesc
+	ch <- c.selfTestDesc
+	ch <- c.upDesc
+	ch <- c.scrapeDurationDesc
+	ch <- c.scrapeErrorDesc
+	ch <- c.upsScrapeDurationDesc
 }
 
 func (c *nutCollector) Collect(ch chan<- prometheus.Metric) {
+	concurrency := c.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
 	for _, host := range c.hosts {
-		conn, err := Dial(host)
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(host HostConfig) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+			defer cancel()
+			c.collectHost(ctx, host, ch)
+		}(host)
+	}
+	wg.Wait()
+}
+
+func (c *nutCollector) collectHost(ctx context.Context, host HostConfig, ch chan<- prometheus.Metric) {
+	start := time.Now()
+	up := 1.0
+	scrapeErr := 0.0
+
+	defer func() {
+		ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, up, host.Host)
+		ch <- prometheus.MustNewConstMetric(c.scrapeDurationDesc, prometheus.GaugeValue, time.Since(start).Seconds(), host.Host)
+		ch <- prometheus.MustNewConstMetric(c.scrapeErrorDesc, prometheus.GaugeValue, scrapeErr, host.Host)
+	}()
+
+	cached, err := c.getConn(ctx, host)
+	if err != nil {
+		log.Printf("error connecting to NUT server: %s", err)
+		up = 0
+		scrapeErr = 1
+		return
+	}
+	conn := cached.client
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			log.Printf("error setting deadline for %s: %s", host.Host, err)
+		}
+	}
+
+	// LIST UPS doubles as the liveness probe for the cached
+	// connection: a stale connection surfaces as io.EOF or a
+	// net.Error here, and is redialed once before giving up.
+	upss, err := conn.UPSs()
+	if isConnError(err) {
+		c.dropConn(host.Host)
+		cached.mu.Unlock()
+
+		cached, err = c.getConn(ctx, host)
 		if err != nil {
 			log.Printf("error connecting to NUT server: %s", err)
-			continue
+			up = 0
+			scrapeErr = 1
+			return
+		}
+		conn = cached.client
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
 		}
-		upss, err := conn.UPSs()
+		upss, err = conn.UPSs()
+	}
+	// cached is locked for exclusive use of conn from here until the
+	// end of the scrape, whether it is the original connection or the
+	// one redialed above: two overlapping scrapes of the same host
+	// must not interleave writes/reads on the same socket.
+	defer cached.mu.Unlock()
+
+	if err != nil {
+		log.Printf("error getting list of UPSs: %s", err)
+		up = 0
+		scrapeErr = 1
+		return
+	}
+
+	for _, ups := range upss {
+		upsStart := time.Now()
+		err := c.readNUT(conn, ups, ch)
+		ch <- prometheus.MustNewConstMetric(c.upsScrapeDurationDesc, prometheus.GaugeValue, time.Since(upsStart).Seconds(), host.Host, ups)
 		if err != nil {
-			log.Printf("error getting list of UPSs: %s", err)
-			_ = conn.Close()
-			continue
+			log.Printf("error reading UPS values: %s", err)
+			up = 0
+			scrapeErr = 1
 		}
-		for _, ups := range upss {
-			if err := c.readNUT(conn, ups, ch); err != nil {
-				log.Printf("error reading UPS values: %s", err)
-			}
+	}
+}
+
+// getConn returns the cached connection for host, reusing it if
+// present and not past maxIdle, or dialing a new one bounded by ctx
+// otherwise. The returned cachedConn is locked for the caller's
+// exclusive use; the caller must call its mu.Unlock once done.
+func (c *nutCollector) getConn(ctx context.Context, host HostConfig) (*cachedConn, error) {
+	c.mu.Lock()
+	if cached, ok := c.conns[host.Host]; ok {
+		if time.Since(cached.lastUsed) <= c.maxIdle {
+			cached.lastUsed = time.Now()
+			c.mu.Unlock()
+			cached.mu.Lock()
+			return cached, nil
 		}
-		_ = conn.Close()
+		delete(c.conns, host.Host)
+		c.mu.Unlock()
+		_ = cached.client.Close()
+	} else {
+		c.mu.Unlock()
+	}
+
+	conn, err := DialContext(ctx, host.Host, host.dialConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	cached := &cachedConn{client: conn, lastUsed: time.Now()}
+	cached.mu.Lock()
+
+	c.mu.Lock()
+	c.conns[host.Host] = cached
+	c.mu.Unlock()
+	return cached, nil
+}
+
+// dropConn closes and discards the cached connection for host, if
+// any, so the next getConn redials.
+func (c *nutCollector) dropConn(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.conns[host]; ok {
+		_ = cached.client.Close()
+		delete(c.conns, host)
+	}
+}
+
+// isConnError reports whether err indicates a connection that can no
+// longer be used and should be redialed.
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Closer is implemented by the collector returned by NewCollector. It
+// logs out of and closes any connections cached for reuse, and
+// should be called once the collector is no longer used, e.g. on
+// exporter shutdown.
+type Closer interface {
+	Close() error
+}
+
+// Close logs out of and closes all cached connections.
+func (c *nutCollector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for host, cached := range c.conns {
+		cached.mu.Lock()
+		_ = cached.client.Logout()
+		_ = cached.client.Close()
+		cached.mu.Unlock()
+		delete(c.conns, host)
 	}
+	return nil
 }
 
 func (c *nutCollector) readNUT(conn *Client, name string, ch chan<- prometheus.Metric) error {
@@ -274,15 +867,12 @@ func (c *nutCollector) readNUT(conn *Client, name string, ch chan<- prometheus.M
 	if err != nil {
 		return err
 	}
-	labels := map[string]string{}
 	values := map[string]float64{}
 	for k := range descriptions {
 		values[k] = 0
 	}
 	for k, v := range vars {
 		switch k {
-		case "device.model", "device.mfr", "device.serial", "device.type":
-			labels[k] = v
 		case "ups.beeper.status":
 			f := float64(-1)
 			switch v {
@@ -319,12 +909,37 @@ func (c *nutCollector) readNUT(conn *Client, name string, ch chan<- prometheus.M
 		}
 	}
 
-	labelValues := []string{
-		labels["device.model"], labels["device.mfr"], labels["device.serial"], labels["device.type"],
+	labelValues := make([]string, len(c.labelVars))
+	for i, lv := range c.labelVars {
+		labelValues[i] = vars[lv]
 	}
 
 	for k, v := range values {
 		ch <- prometheus.MustNewConstMetric(c.descs[k], prometheus.GaugeValue, v, labelValues...)
 	}
+
+	status := map[string]bool{}
+	for _, flag := range strings.Fields(vars["ups.status"]) {
+		status[flag] = true
+	}
+	for _, flag := range statusFlags {
+		v := 0.0
+		if status[flag] {
+			v = 1
+		}
+		fv := append(append([]string{}, labelValues...), flag)
+		ch <- prometheus.MustNewConstMetric(c.statusDesc, prometheus.GaugeValue, v, fv...)
+	}
+
+	result := vars["ups.test.result"]
+	for _, r := range selfTestResults {
+		v := 0.0
+		if r == result {
+			v = 1
+		}
+		fv := append(append([]string{}, labelValues...), r)
+		ch <- prometheus.MustNewConstMetric(c.selfTestDesc, prometheus.GaugeValue, v, fv...)
+	}
+
 	return nil
 }